@@ -14,37 +14,52 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ambitiousfew/goll/internal/metrics"
 	"github.com/ambitiousfew/goll/internal/ollama"
 	"github.com/ambitiousfew/goll/internal/tool"
 )
 
+// configLoaderCacheSize bounds the per-run LRU of parsed folder configs, large
+// enough to cover a deep -r recursion without re-parsing config.json/system.txt
+// for folders already visited earlier in the chain.
+const configLoaderCacheSize = 256
+
 type args struct {
 	folders []string
 	multi   bool
 	prompt  string
 	verbose bool
-	recurse bool
 }
 
 func main() {
+	// "goll serve" starts an HTTP server exposing the chain runner instead of
+	// running the one-shot CLI flow below.
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := serve(os.Args[2:]); err != nil {
+			fmt.Println("Error running goll serve: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Define and parse command-line flags
 	folder := flag.String("f", "", "One or more comma seperated folder names. Limit one parnet folder if using with -r flag")
 	prompt := flag.String("p", "", "Optional.  Initial prompt text to use instead of reading from prompt.txt file.")
 	verbose := flag.Bool("v", false, "Optional. Print output to stdout.")
 	recurse := flag.Bool("r", false, "Optional. Recurse through subfolders. If set -f can only have one folder.")
+	pipelineFile := flag.String("pipeline", "", "Optional. Path to a pipeline.json file describing a DAG of nodes. Runs the DAG instead of the -f folder chain.")
+	jobs := flag.Int("j", 1, "Optional. Max pipeline nodes to run concurrently when using -pipeline.")
 	flag.Parse()
 
-	// Ensure at least one folder name is provided
-	if *folder == "" {
+	// Ensure at least one folder name is provided, unless a DAG pipeline was given instead
+	if *pipelineFile == "" && *folder == "" {
 		fmt.Println("Error: At least one folder is required")
 		flag.Usage()
 		os.Exit(1)
@@ -80,6 +95,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// -pipeline runs a declarative DAG instead of the linear -f folder chain.
+	if *pipelineFile != "" {
+		if err := runPipeline(settings, *pipelineFile, *prompt, *jobs); err != nil {
+			fmt.Println("Error running goll pipeline: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Ensure each folder exists in the folder_base_path
 	for _, folder := range folders {
 		folderPath := filepath.Join(settings.FolderBase, folder)
@@ -110,11 +134,21 @@ func main() {
 		folders = subfolders
 	}
 
+	// The plain comma-separated -f chain (no -r) is a thin wrapper around the
+	// same DAG engine -pipeline uses: each folder becomes a Linear pipeline node
+	// relaying the previous node's output in memory instead of through prompt.txt.
+	if !*recurse {
+		if err := runChain(settings, folders, *prompt, *verbose); err != nil {
+			fmt.Println("Error running goll: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	args := args{
 		folders: folders,
 		prompt:  *prompt,
 		verbose: *verbose,
-		recurse: *recurse,
 	}
 
 	// Run the tool for each folder
@@ -126,9 +160,18 @@ func main() {
 
 }
 
-// run function generates a response for each folder in the folders slice.
+// run generates a response for each folder in the folders slice. It only
+// handles the -r recursion mode now: each subfolder runs independently (fed
+// either the shared -p prompt or its own prompt.txt), which does not fit the
+// DAG's chained-output model that runChain uses for the plain -f case.
 func run(settings tool.Settings, args args) error {
 
+	// Build the metrics writer once for the whole chain.
+	metricsWriter, err := metrics.NewWriter(settings.Metrics)
+	if err != nil {
+		return fmt.Errorf("error building metrics writer: %v", err)
+	}
+
 	// Create a context
 	// Signal worker is in charge of cancelling the context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -151,122 +194,31 @@ func run(settings tool.Settings, args args) error {
 	defer close(spin)
 	go spinner(ctx, spin)
 
-	// Loop through each folder and generate a response
-	for index, folder := range args.folders {
-		// If firstPrompt is provided, set the prompt text for first folder
-		// Generate will ignore empty prompt text and use prompt.txt file
+	// Determine the folder base shared by every folder in this chain so a single
+	// config loader can cache parsed folder configs across the whole run.
+	// Subfolders discovered by -r already carry the base prefix
+	// (folderBase/parent/sub), so no further base is joined in front of them,
+	// whether each subfolder uses the shared -p prompt or its own prompt.txt.
+	chainFolderBase := ""
+	loader := ollama.NewCachingLoader(ollama.FSLoader{Base: chainFolderBase}, configLoaderCacheSize)
+
+	// Loop through each subfolder and generate a response. Each subfolder runs
+	// independently: either the shared -p prompt, or its own prompt.txt file.
+	for _, folder := range args.folders {
 		var prompt string
-		if index == 0 && args.prompt != "" && !args.recurse {
-			prompt = args.prompt
-		}
-
-		// If we are recursing and prompt is provided, set the prompt text for all folders
-		// Generate will ignore empty prompt text and use prompt.txt file
-		folderBase := settings.FolderBase
-		if args.recurse && args.prompt != "" {
+		if args.prompt != "" {
 			prompt = args.prompt
-			// subfolders will be in the format: folderBase/parentFolder/subfolder
-			folderBase = ""
-		}
-
-		// Create a new ollama generate instance
-		gen, err := ollama.NewGenerate(
-			folder,
-			ollama.WithPrompt(prompt),
-			ollama.WithAPIBase(settings.APIBase),
-			ollama.WithFolderBase(folderBase),
-			ollama.WithClient(http.Client{}),
-			ollama.WithTimeout(settings.Timeout),
-		)
-		if err != nil {
-			return fmt.Errorf("error creating generate instance: %v", err)
-		}
-
-		modelConfig := gen.Config()
-
-		// Pretty print modelConfig
-		modelConfigJSON, err := json.MarshalIndent(modelConfig, "", "  ")
-		if err != nil {
-			return fmt.Errorf("error marshalling modelConfig: %v", err)
-		}
-
-		if args.verbose {
-			fmt.Printf("Generating response using folder: %s\n  With Model Config: %v\n", folder, string(modelConfigJSON))
 		}
+		folderBase := chainFolderBase
 
-		// Start the spinner
-		select {
-		case <-ctx.Done():
-			return nil
-		case spin <- true:
-		}
-
-		// Send the request to the ollama generate API
-		resp, err := gen.Post(ctx)
+		_, err := tool.RunFolder(ctx, settings, folderBase, folder, prompt, loader, metricsWriter, args.verbose, spin)
 		if err != nil {
-			return fmt.Errorf("error generating response: %v", err)
-		}
-
-		// Stop the spinner
-		select {
-		case <-ctx.Done():
-			return nil
-		case spin <- false:
-		}
-
-		// convert evalution time from nanoseconds to seconds as float
-		evalTime := float64(resp.EvalDuration) / 1e9
-		// Compute tokens per second
-		tps := float64(resp.EvalCount) / evalTime
-
-		if args.verbose {
-			// Print the response and metrics
-			fmt.Printf("\n\nResponse: %s", resp.Output)
-			fmt.Printf("\n\nGenerated %d tokens in %.2f seconds", resp.EvalCount, evalTime)
-			fmt.Printf("\nTokens per second: %.2f\n", tps)
-		}
-
-		// If there is a next folder and we are not recursing, write the response to prompt.txt file in the next folder
-		if index < len(args.folders)-1 && !args.recurse {
-			nextFolder := args.folders[index+1]
-			nextFolderPath := filepath.Join(folderBase, nextFolder)
-			nextPromptFilePath := filepath.Join(nextFolderPath, "prompt.txt")
-
-			// Remove content wrapped with <think></think> tags
-			re := regexp.MustCompile(`(?s)<think>.*?</think>`)
-			cleanedOutput := re.ReplaceAllString(resp.Output, "")
-
-			err = os.WriteFile(nextPromptFilePath, []byte(cleanedOutput), 0644)
-			if err != nil {
-				return fmt.Errorf("error writing prompt.txt: %v", err)
-			}
-			if args.verbose {
-				fmt.Printf("Response written to %s\n", nextPromptFilePath)
+			if ctx.Err() != nil {
+				return nil
 			}
+			return fmt.Errorf("error running folder %s: %w", folder, err)
 		}
 
-		// Write to output_date_time.log file
-		outputLogFileName := fmt.Sprintf("output_%s.log", time.Now().Format("2006-01-02_15-04-05"))
-		outputLogPath := filepath.Join(folderBase, folder, outputLogFileName)
-		outputLog := fmt.Sprintf(
-			"Prompt: %s\n\n"+
-				"Response: %s\n\n"+
-				"Generated %d tokens in %.2f seconds\n"+
-				"Tokens per second: %.2f\n"+
-				"Using model config: %s\n",
-			gen.Prompt(),
-			resp.Output,
-			resp.EvalCount,
-			evalTime,
-			tps,
-			modelConfigJSON,
-		)
-		err = os.WriteFile(outputLogPath, []byte(outputLog), 0644)
-		if err != nil {
-			return fmt.Errorf("error writing output.log: %v", err)
-		}
-		fmt.Printf("Output written to %s\n", outputLogPath)
-
 		fmt.Printf("%s completed successfully\n\n", folder)
 	}
 
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/ambitiousfew/goll/internal/metrics"
+	"github.com/ambitiousfew/goll/internal/pipeline"
+	"github.com/ambitiousfew/goll/internal/tool"
+)
+
+// runPipeline loads the DAG pipeline definition at pipelineFile and executes it,
+// using initialPrompt as the "$prompt" input and running up to jobs nodes
+// concurrently. Each node's log is written under a timestamped run directory.
+func runPipeline(settings tool.Settings, pipelineFile, initialPrompt string, jobs int) error {
+	pl, err := pipeline.Load(pipelineFile)
+	if err != nil {
+		return err
+	}
+	return runDAG(settings, pl, initialPrompt, jobs, true)
+}
+
+// runChain is the -f comma-separated folder chain, reduced to a thin wrapper
+// that synthesizes the equivalent linear Pipeline and executes it through the
+// same DAG engine as -pipeline, instead of its own folder loop relaying
+// output through prompt.txt files.
+func runChain(settings tool.Settings, folders []string, initialPrompt string, verbose bool) error {
+	pl := pipeline.Linear(folders)
+	return runDAG(settings, pl, initialPrompt, 1, verbose)
+}
+
+// runDAG executes pl, using initialPrompt as the "$prompt" input and running up
+// to jobs nodes concurrently. Each node's log is written under a timestamped
+// run directory.
+func runDAG(settings tool.Settings, pl pipeline.Pipeline, initialPrompt string, jobs int, verbose bool) error {
+	metricsWriter, err := metrics.NewWriter(settings.Metrics)
+	if err != nil {
+		return fmt.Errorf("error building metrics writer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	defer close(sigChan)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		signal.Stop(sigChan)
+		fmt.Print("Received exit signal. Cancelling context.\n")
+		cancel()
+	}()
+
+	runDir := filepath.Join("runs", time.Now().Format("2006-01-02_15-04-05"))
+
+	result, err := pipeline.Run(ctx, pl, pipeline.Options{
+		Settings:      settings,
+		Prompt:        initialPrompt,
+		RunDir:        runDir,
+		Parallelism:   jobs,
+		MetricsWriter: metricsWriter,
+		Verbose:       verbose,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, node := range pl.Nodes {
+		if result.Skipped[node.ID] {
+			fmt.Printf("%s skipped\n", node.ID)
+			continue
+		}
+		if nr := result.Outputs[node.ID]; nr.Err != nil {
+			fmt.Printf("%s failed: %v\n", node.ID, nr.Err)
+		}
+	}
+
+	fmt.Printf("Pipeline run written to %s\n", runDir)
+	return nil
+}
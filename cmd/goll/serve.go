@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/ambitiousfew/goll/internal/tool"
+)
+
+// serve parses the "goll serve" subcommand flags and starts the HTTP server
+// exposing the chain runner until the process is interrupted.
+func serve(serveArgs []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Address to listen on.")
+	if err := fs.Parse(serveArgs); err != nil {
+		return err
+	}
+
+	settingsContent, err := os.ReadFile("settings.json")
+	if err != nil {
+		return fmt.Errorf("error reading settings.json: %w", err)
+	}
+	settings := tool.Settings{}
+	if err := json.Unmarshal(settingsContent, &settings); err != nil {
+		return fmt.Errorf("error unmarshalling settings.json: %w", err)
+	}
+
+	server := tool.NewServer(settings)
+	fmt.Printf("Listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, server.Handler())
+}
@@ -0,0 +1,55 @@
+// Package metrics emits per-call generation metrics in InfluxDB line protocol to a
+// configurable sink (stdout, a file, or an InfluxDB HTTP endpoint) so goll stays
+// observable when it is used as a longer agent chain rather than a one-shot CLI.
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambitiousfew/goll/internal/ollama"
+)
+
+// Point is a single InfluxDB line-protocol point describing one goll generation call.
+type Point struct {
+	Model    string
+	Folder   string
+	Response ollama.Response
+}
+
+// NewPoint builds a Point from resp and the folder/model labels that produced it.
+func NewPoint(resp ollama.Response, folder, model string) Point {
+	return Point{Model: model, Folder: folder, Response: resp}
+}
+
+// Line encodes the point as a single InfluxDB line-protocol line: measurement
+// "goll_generation", tagged by model and folder, fields for the eval/prompt-eval
+// counts and durations plus a derived tokens_per_second, timestamped from
+// Response.CreatedAt.
+func (p Point) Line() string {
+	evalSeconds := float64(p.Response.EvalDuration) / 1e9
+	var tokensPerSecond float64
+	if evalSeconds != 0 {
+		tokensPerSecond = float64(p.Response.EvalCount) / evalSeconds
+	}
+
+	return fmt.Sprintf(
+		"goll_generation,model=%s,folder=%s eval_count=%di,eval_duration_ns=%di,prompt_eval_count=%di,prompt_eval_duration_ns=%di,total_duration_ns=%di,tokens_per_second=%f %d",
+		escapeTag(p.Model),
+		escapeTag(p.Folder),
+		p.Response.EvalCount,
+		p.Response.EvalDuration,
+		p.Response.PromptEvalCount,
+		p.Response.PromptEvalDuration,
+		p.Response.TotalDuration,
+		tokensPerSecond,
+		p.Response.CreatedAt.UnixNano(),
+	)
+}
+
+// escapeTag escapes the characters InfluxDB line protocol treats as special in tag
+// keys and values: commas, spaces, and equals signs.
+func escapeTag(s string) string {
+	replacer := strings.NewReplacer(",", "\\,", " ", "\\ ", "=", "\\=")
+	return replacer.Replace(s)
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Writer emits a Point to a metrics sink.
+type Writer interface {
+	Write(ctx context.Context, p Point) error
+}
+
+// Auth holds HTTP basic auth credentials for the HTTP writer.
+type Auth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Config configures which Writer NewWriter builds. Set from the "metrics" block
+// of settings.json.
+type Config struct {
+	Type     string `json:"type"`     // "stdout", "file", or "http". Defaults to "stdout".
+	Endpoint string `json:"endpoint"` // file path for "file", InfluxDB /write URL for "http"
+	Auth     *Auth  `json:"auth,omitempty"`
+}
+
+// NewWriter builds the Writer configured by cfg. An empty Type defaults to stdout.
+func NewWriter(cfg Config) (Writer, error) {
+	switch cfg.Type {
+	case "", "stdout":
+		return StdoutWriter{}, nil
+	case "file":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("metrics: file endpoint is required")
+		}
+		return FileWriter{path: cfg.Endpoint}, nil
+	case "http":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("metrics: http endpoint is required")
+		}
+		w := HTTPWriter{endpoint: cfg.Endpoint, client: http.Client{}}
+		if cfg.Auth != nil {
+			w.username = cfg.Auth.Username
+			w.password = cfg.Auth.Password
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("metrics: unknown type %q", cfg.Type)
+	}
+}
+
+// StdoutWriter prints each point's line-protocol encoding to stdout.
+type StdoutWriter struct{}
+
+// Write implements Writer.
+func (w StdoutWriter) Write(_ context.Context, p Point) error {
+	_, err := fmt.Println(p.Line())
+	return err
+}
+
+// FileWriter appends each point's line-protocol encoding to a file, creating it
+// if it does not already exist.
+type FileWriter struct {
+	path string
+}
+
+// Write implements Writer.
+func (w FileWriter) Write(_ context.Context, p Point) error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening metrics file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(p.Line() + "\n"); err != nil {
+		return fmt.Errorf("error writing metrics file: %w", err)
+	}
+	return nil
+}
+
+// HTTPWriter POSTs each point's line-protocol encoding to an InfluxDB /write
+// endpoint, optionally authenticating with HTTP basic auth.
+type HTTPWriter struct {
+	endpoint string
+	username string
+	password string
+	client   http.Client
+}
+
+// Write implements Writer.
+func (w HTTPWriter) Write(ctx context.Context, p Point) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.endpoint, strings.NewReader(p.Line()+"\n"))
+	if err != nil {
+		return fmt.Errorf("error creating metrics request: %w", err)
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending metrics request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package ollama
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// ConfigLoader loads a folder's ModelConfig (config.json + system.txt + optional
+// format.json) and prompt.txt by folder name. It is the extension point behind
+// WithConfigLoader: FSLoader is the default, EmbedLoader and HTTPLoader let a
+// build serve folders from an embedded filesystem or a remote config server
+// instead of the local disk.
+type ConfigLoader interface {
+	LoadConfig(folder string) (ModelConfig, error)
+	LoadPrompt(folder string) (string, error)
+}
+
+// FSLoader is the default ConfigLoader, reading folders from Base on the local
+// filesystem. It reproduces the behavior NewGenerate and Generate.config used
+// to implement directly via os.ReadFile.
+type FSLoader struct {
+	Base string
+}
+
+// LoadConfig reads folder/config.json, folder/system.txt, and the optional
+// folder/format.json under l.Base.
+func (l FSLoader) LoadConfig(folder string) (ModelConfig, error) {
+	empty := ModelConfig{}
+	dir := filepath.Join(l.Base, folder)
+
+	configContent, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return empty, fmt.Errorf("error reading config.json: %w", err)
+	}
+
+	config := ModelConfig{Options: NewModelOptions()}
+	if err := json.Unmarshal(configContent, &config); err != nil {
+		return empty, fmt.Errorf("error unmarshalling config.json: %w", err)
+	}
+
+	systemPromptFromFile, err := os.ReadFile(filepath.Join(dir, "system.txt"))
+	if err != nil {
+		return empty, fmt.Errorf("error reading system.txt: %w", err)
+	}
+	config.System = string(systemPromptFromFile)
+
+	if config.OutputFormat.Type == "" {
+		formatFromFile, err := os.ReadFile(filepath.Join(dir, "format.json"))
+		if err == nil {
+			var format OutputFormat
+			if err := json.Unmarshal(formatFromFile, &format); err != nil {
+				return empty, fmt.Errorf("error unmarshalling format.json: %w", err)
+			}
+			config.OutputFormat = format
+		}
+	}
+
+	return config, nil
+}
+
+// LoadPrompt reads folder/prompt.txt under l.Base.
+func (l FSLoader) LoadPrompt(folder string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(l.Base, folder, "prompt.txt"))
+	if err != nil {
+		return "", fmt.Errorf("error reading prompt.txt: %w", err)
+	}
+	return string(content), nil
+}
+
+// EmbedLoader is a ConfigLoader backed by an embed.FS, for builds that bundle
+// their folders into the binary instead of reading them from disk at runtime.
+// embed.FS always uses forward-slash paths regardless of OS, so it is joined
+// with path rather than filepath.
+type EmbedLoader struct {
+	FS   embed.FS
+	Base string
+}
+
+// LoadConfig reads folder/config.json, folder/system.txt, and the optional
+// folder/format.json from l.FS under l.Base.
+func (l EmbedLoader) LoadConfig(folder string) (ModelConfig, error) {
+	empty := ModelConfig{}
+	dir := path.Join(l.Base, folder)
+
+	configContent, err := l.FS.ReadFile(path.Join(dir, "config.json"))
+	if err != nil {
+		return empty, fmt.Errorf("error reading config.json: %w", err)
+	}
+
+	config := ModelConfig{Options: NewModelOptions()}
+	if err := json.Unmarshal(configContent, &config); err != nil {
+		return empty, fmt.Errorf("error unmarshalling config.json: %w", err)
+	}
+
+	systemPromptFromFile, err := l.FS.ReadFile(path.Join(dir, "system.txt"))
+	if err != nil {
+		return empty, fmt.Errorf("error reading system.txt: %w", err)
+	}
+	config.System = string(systemPromptFromFile)
+
+	if config.OutputFormat.Type == "" {
+		formatFromFile, err := l.FS.ReadFile(path.Join(dir, "format.json"))
+		if err == nil {
+			var format OutputFormat
+			if err := json.Unmarshal(formatFromFile, &format); err != nil {
+				return empty, fmt.Errorf("error unmarshalling format.json: %w", err)
+			}
+			config.OutputFormat = format
+		}
+	}
+
+	return config, nil
+}
+
+// LoadPrompt reads folder/prompt.txt from l.FS under l.Base.
+func (l EmbedLoader) LoadPrompt(folder string) (string, error) {
+	content, err := l.FS.ReadFile(path.Join(l.Base, folder, "prompt.txt"))
+	if err != nil {
+		return "", fmt.Errorf("error reading prompt.txt: %w", err)
+	}
+	return string(content), nil
+}
+
+// HTTPLoader is a ConfigLoader that fetches a folder's files from a remote
+// config server, GETing BaseURL/<folder>/<file> for each of config.json,
+// system.txt, and the optional format.json and prompt.txt.
+type HTTPLoader struct {
+	BaseURL string
+	Client  http.Client
+}
+
+// get fetches BaseURL/folder/file, returning an error unless the response is
+// 200 OK.
+func (l HTTPLoader) get(folder, file string) ([]byte, error) {
+	resp, err := l.Client.Get(l.BaseURL + "/" + folder + "/" + file)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", file, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %s: status code %d", file, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", file, err)
+	}
+	return body, nil
+}
+
+// LoadConfig fetches folder/config.json, folder/system.txt, and the optional
+// folder/format.json from l.BaseURL.
+func (l HTTPLoader) LoadConfig(folder string) (ModelConfig, error) {
+	empty := ModelConfig{}
+
+	configContent, err := l.get(folder, "config.json")
+	if err != nil {
+		return empty, err
+	}
+
+	config := ModelConfig{Options: NewModelOptions()}
+	if err := json.Unmarshal(configContent, &config); err != nil {
+		return empty, fmt.Errorf("error unmarshalling config.json: %w", err)
+	}
+
+	systemPromptFromFile, err := l.get(folder, "system.txt")
+	if err != nil {
+		return empty, err
+	}
+	config.System = string(systemPromptFromFile)
+
+	if config.OutputFormat.Type == "" {
+		formatFromFile, err := l.get(folder, "format.json")
+		if err == nil {
+			var format OutputFormat
+			if err := json.Unmarshal(formatFromFile, &format); err != nil {
+				return empty, fmt.Errorf("error unmarshalling format.json: %w", err)
+			}
+			config.OutputFormat = format
+		}
+	}
+
+	return config, nil
+}
+
+// LoadPrompt fetches folder/prompt.txt from l.BaseURL.
+func (l HTTPLoader) LoadPrompt(folder string) (string, error) {
+	content, err := l.get(folder, "prompt.txt")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// CachingLoader wraps another ConfigLoader and caches its LoadConfig results
+// in an LRU of at most Size entries, so recursive runs over hundreds of
+// subfolders don't re-read and re-parse the same config.json/system.txt on
+// every folder visit. LoadPrompt is never cached: prompt.txt is the part of a
+// folder a chain overwrites between runs, so caching it would risk serving a
+// stale prompt.
+type CachingLoader struct {
+	inner ConfigLoader
+	size  int
+
+	mu    sync.Mutex
+	cache map[string]ModelConfig
+	order []string // folder names, oldest first
+}
+
+// NewCachingLoader wraps inner with an LRU cache of at most size LoadConfig
+// results.
+func NewCachingLoader(inner ConfigLoader, size int) *CachingLoader {
+	if size < 1 {
+		size = 1
+	}
+	return &CachingLoader{
+		inner: inner,
+		size:  size,
+		cache: make(map[string]ModelConfig, size),
+	}
+}
+
+// LoadConfig returns the cached ModelConfig for folder if present, otherwise
+// loads it from the inner ConfigLoader and caches the result, evicting the
+// least recently used entry if the cache is full.
+func (l *CachingLoader) LoadConfig(folder string) (ModelConfig, error) {
+	l.mu.Lock()
+	if config, ok := l.cache[folder]; ok {
+		l.touch(folder)
+		l.mu.Unlock()
+		return config, nil
+	}
+	l.mu.Unlock()
+
+	config, err := l.inner.LoadConfig(folder)
+	if err != nil {
+		return ModelConfig{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[folder]; !ok && len(l.cache) >= l.size {
+		l.evictOldest()
+	}
+	l.cache[folder] = config
+	l.touch(folder)
+
+	return config, nil
+}
+
+// LoadPrompt always delegates to the inner ConfigLoader; see the CachingLoader
+// doc comment for why prompts are never cached.
+func (l *CachingLoader) LoadPrompt(folder string) (string, error) {
+	return l.inner.LoadPrompt(folder)
+}
+
+// touch moves folder to the most-recently-used end of l.order. Caller must
+// hold l.mu.
+func (l *CachingLoader) touch(folder string) {
+	for i, f := range l.order {
+		if f == folder {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+	l.order = append(l.order, folder)
+}
+
+// evictOldest removes the least recently used entry. Caller must hold l.mu.
+func (l *CachingLoader) evictOldest() {
+	if len(l.order) == 0 {
+		return
+	}
+	oldest := l.order[0]
+	l.order = l.order[1:]
+	delete(l.cache, oldest)
+}
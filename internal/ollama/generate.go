@@ -6,9 +6,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -75,6 +75,22 @@ type Response struct {
 	EvalDuration       int64     `json:"eval_duration"`
 }
 
+// StreamChunk struct contains a single partial object decoded from the Ollama NDJSON stream.
+// EvalCount and the other stat fields are only populated by Ollama on the terminal chunk
+// where Done is true.
+type StreamChunk struct {
+	Model              string    `json:"model"`
+	CreatedAt          time.Time `json:"created_at"`
+	Response           string    `json:"response"`
+	Done               bool      `json:"done"`
+	TotalDuration      int64     `json:"total_duration"`
+	LoadDuration       int       `json:"load_duration"`
+	PromptEvalCount    int       `json:"prompt_eval_count"`
+	PromptEvalDuration int       `json:"prompt_eval_duration"`
+	EvalCount          int       `json:"eval_count"`
+	EvalDuration       int64     `json:"eval_duration"`
+}
+
 // Generate is a struct that contains the configuration for the Generate struct.
 // It also contains the HTTP client, API base URL, and folder base path.
 type Generate struct {
@@ -85,6 +101,11 @@ type Generate struct {
 	apiBase     string        // API base URL
 	folderBase  string        // folder base path
 	timeout     time.Duration // timeout for the request
+	maxAttempts int           // number of attempts Post makes before giving up, set via WithRetry
+	minSleep    time.Duration // lower bound for the default pacer, set via WithRetry
+	maxSleep    time.Duration // upper bound for the default pacer, set via WithRetry
+	backoff     Backoff       // retry strategy, set via WithBackoff. Defaults to a pacer built from minSleep/maxSleep.
+	loader      ConfigLoader  // loads ModelConfig/prompt by folder name, set via WithConfigLoader. Defaults to FSLoader{Base: folderBase}.
 }
 
 // Option is a function that takes a pointer to a Generate struct and modifies it.
@@ -100,6 +121,7 @@ func NewGenerate(folder string, options ...Option) (Generate, error) {
 		apiBase:     "",
 		folderBase:  "",
 		timeout:     300 * time.Second,
+		maxAttempts: 1,
 	}
 
 	for _, opt := range options {
@@ -110,17 +132,21 @@ func NewGenerate(folder string, options ...Option) (Generate, error) {
 		return g, fmt.Errorf("API base URL is required")
 	}
 
-	// If we have a prompt, use it. Otherwise, read the prompt.txt file.
+	if g.loader == nil {
+		g.loader = FSLoader{Base: g.folderBase}
+	}
+
+	// If we have a prompt, use it. Otherwise, load it from the folder.
 	if g.Prompt == "" {
-		promptFromFile, err := os.ReadFile(filepath.Join(g.folderBase, g.folder, "prompt.txt"))
+		promptFromFile, err := g.loader.LoadPrompt(g.folder)
 		if err != nil {
-			return g, fmt.Errorf("error reading prompt.txt: %w", err)
+			return g, fmt.Errorf("error loading prompt: %w", err)
 		}
-		g.Prompt = string(promptFromFile)
+		g.Prompt = promptFromFile
 	}
 
 	// Get the model config.
-	config, err := g.config()
+	config, err := g.loader.LoadConfig(g.folder)
 	if err != nil {
 		return g, fmt.Errorf("error getting model config: %w", err)
 	}
@@ -164,49 +190,147 @@ func WithTimeout(timeout int) Option {
 	}
 }
 
-// config reads the config.json file from the folder and returns a ModelConfig struct or an error.
-func (g *Generate) config() (ModelConfig, error) {
-	// Read the config.json file from the path and unmarshal it into a modelConfig struct.
-	empty := ModelConfig{}
-	configDirPath := filepath.Join(g.folderBase, g.folder)
-	configContent, err := os.ReadFile(filepath.Join(configDirPath, "config.json"))
-	if err != nil {
-		return empty, fmt.Errorf("error reading config.json: %w", err)
+// WithRetry enables retrying Post on transient failures (see ShouldRetry and
+// Ollama's "model is loading" response) up to maxAttempts times, sleeping between
+// attempts according to the default pacer bounded by minSleep and maxSleep. Use
+// WithBackoff to plug in a custom strategy instead of the default pacer.
+func WithRetry(maxAttempts int, minSleep, maxSleep time.Duration) Option {
+	return func(g *Generate) {
+		g.maxAttempts = maxAttempts
+		g.minSleep = minSleep
+		g.maxSleep = maxSleep
 	}
+}
 
-	config := ModelConfig{Options: NewModelOptions()}
-	err = json.Unmarshal(configContent, &config)
-	if err != nil {
-		return empty, fmt.Errorf("error unmarshalling config.json: %w", err)
+// WithBackoff overrides the default pacer used between retry attempts enabled by
+// WithRetry.
+func WithBackoff(b Backoff) Option {
+	return func(g *Generate) {
+		g.backoff = b
 	}
+}
+
+// WithConfigLoader overrides how the folder's ModelConfig and prompt are loaded.
+// Defaults to FSLoader{Base: folderBase}. Wrap loader in NewCachingLoader to
+// avoid re-parsing the same folder's config.json/system.txt across many runs.
+func WithConfigLoader(loader ConfigLoader) Option {
+	return func(g *Generate) {
+		g.loader = loader
+	}
+}
 
-	// Read the system.txt file.
-	systemPromptFromFile, err := os.ReadFile(filepath.Join(g.folderBase, g.folder, "system.txt"))
+// Post sends a POST request with context to the Ollama API and returns a Response struct
+// or an error. If WithRetry was used to configure the Generate struct, transient failures
+// (see ShouldRetry and Ollama's "model is loading" response) are retried up to maxAttempts
+// times, sleeping between attempts according to the backoff strategy and honoring ctx.Done().
+func (g *Generate) Post(ctx context.Context) (Response, error) {
+	empty := Response{}
+
+	var format any
+	if g.ModelConfig.OutputFormat.Type != "" {
+		format = g.ModelConfig.OutputFormat
+	} else {
+		format = ""
+	}
+
+	// Build the request
+	req := request{
+		Model:   g.ModelConfig.Model,
+		Options: g.ModelConfig.Options,
+		Prompt:  g.Prompt,
+		Stream:  false,
+		System:  g.ModelConfig.System,
+		Format:  format,
+		Raw:     false,
+	}
+
+	// Marshal the request into JSON.
+	reqJSON, err := json.Marshal(req)
 	if err != nil {
-		return empty, fmt.Errorf("error reading system.txt: %w", err)
+		return empty, fmt.Errorf("error marshalling request: %w", err)
 	}
-	config.System = string(systemPromptFromFile)
-
-	// If optional format.json file is present in the folder, use it.
-	if g.ModelConfig.OutputFormat.Type == "" {
-		formatFromFile, err := os.ReadFile(filepath.Join(g.folderBase, g.folder, "format.json"))
-		if err == nil {
-			var format OutputFormat
-			err := json.Unmarshal(formatFromFile, &format)
-			if err != nil {
-				return empty, fmt.Errorf("error unmarshalling format.json: %w", err)
+
+	maxAttempts := g.maxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backoff := g.backoff
+	if backoff == nil {
+		backoff = newPacer(g.minSleep, g.maxSleep, true)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, body, doErr := g.doPost(ctx, reqJSON)
+
+		if doErr == nil && resp.StatusCode == http.StatusOK && !isModelLoadingBody(body) {
+			response := Response{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				return empty, fmt.Errorf("error unmarshalling response body: %w", err)
 			}
-			// Set the format in the config.
-			config.OutputFormat = format
+			return response, nil
+		}
+
+		switch {
+		case doErr != nil:
+			lastErr = doErr
+		case isModelLoadingBody(body):
+			lastErr = fmt.Errorf("model is loading")
+		default:
+			lastErr = fmt.Errorf("error response status code: %d", resp.StatusCode)
+		}
+
+		retry := isModelLoadingBody(body) || ShouldRetry(resp, doErr)
+		if !retry || attempt == maxAttempts {
+			return empty, lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return empty, ctx.Err()
+		case <-time.After(backoff.Next(attempt)):
 		}
 	}
 
-	return config, nil
+	return empty, lastErr
+}
+
+// doPost sends a single POST attempt and returns the response together with its
+// fully-read body so callers can both classify the outcome (ShouldRetry, the
+// "model is loading" body) and decode it without consuming the body twice.
+func (g *Generate) doPost(ctx context.Context, reqJSON []byte) (*http.Response, []byte, error) {
+	// Create a new context with a timeout from parent context.
+	requestCtx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	// Create a new request with context and JSON body.
+	request, err := http.NewRequestWithContext(requestCtx, "POST", g.apiBase+"/generate", bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating POST request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
 
+	// Send the request.
+	resp, err := g.client.Do(request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error sending POST request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	return resp, body, nil
 }
 
-// Post sends a POST request with context to the Ollama API and returns a Response struct or an error.
-func (g *Generate) Post(ctx context.Context) (Response, error) {
+// Stream sends a POST request with Stream set to true and decodes the Ollama NDJSON
+// response line-by-line, invoking fn for each partial chunk as it arrives. It returns
+// an aggregated Response built from the terminal chunk where Done is true. If ctx is
+// cancelled before the stream completes, the HTTP response body is closed and ctx.Err()
+// is returned.
+func (g *Generate) Stream(ctx context.Context, fn func(chunk StreamChunk) error) (Response, error) {
 	empty := Response{}
 
 	var format any
@@ -221,14 +345,14 @@ func (g *Generate) Post(ctx context.Context) (Response, error) {
 		Model:   g.ModelConfig.Model,
 		Options: g.ModelConfig.Options,
 		Prompt:  g.Prompt,
-		Stream:  false,
+		Stream:  true,
 		System:  g.ModelConfig.System,
 		Format:  format,
 		Raw:     false,
 	}
 
 	// Create a new context with a timeout from parent context.
-	requestCtx, cancel := context.WithTimeout(ctx, time.Duration(g.timeout)*time.Second)
+	requestCtx, cancel := context.WithTimeout(ctx, g.timeout)
 	defer cancel()
 
 	// Marshal the request into JSON.
@@ -238,14 +362,14 @@ func (g *Generate) Post(ctx context.Context) (Response, error) {
 	}
 
 	// Create a new request with context and JSON body.
-	request, err := http.NewRequestWithContext(requestCtx, "POST", g.apiBase+"/generate", bytes.NewReader(reqJSON))
+	httpReq, err := http.NewRequestWithContext(requestCtx, "POST", g.apiBase+"/generate", bytes.NewReader(reqJSON))
 	if err != nil {
 		return empty, fmt.Errorf("error creating POST request: %w", err)
 	}
-	request.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Send the request.
-	resp, err := g.client.Do(request)
+	resp, err := g.client.Do(httpReq)
 	if err != nil {
 		return empty, fmt.Errorf("error sending POST request: %w", err)
 	}
@@ -256,12 +380,51 @@ func (g *Generate) Post(ctx context.Context) (Response, error) {
 		return empty, fmt.Errorf("error response status code: %d", resp.StatusCode)
 	}
 
-	// Unmarshal the response body into a Response struct.
-	response := Response{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		return empty, fmt.Errorf("error unmarshalling response body: %w", err)
+	var output strings.Builder
+	var final StreamChunk
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		// Bail out without leaking the body if the caller's context is done.
+		select {
+		case <-ctx.Done():
+			return empty, ctx.Err()
+		default:
+		}
+
+		var chunk StreamChunk
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			if ctx.Err() != nil {
+				return empty, ctx.Err()
+			}
+			return empty, fmt.Errorf("error decoding stream chunk: %w", err)
+		}
+
+		output.WriteString(chunk.Response)
+
+		if err := fn(chunk); err != nil {
+			return empty, fmt.Errorf("error in stream callback: %w", err)
+		}
+
+		if chunk.Done {
+			final = chunk
+			break
+		}
 	}
 
-	return response, nil
+	return Response{
+		Model:              final.Model,
+		CreatedAt:          final.CreatedAt,
+		Output:             output.String(),
+		Done:               final.Done,
+		TotalDuration:      final.TotalDuration,
+		LoadDuration:       final.LoadDuration,
+		PromptEvalCount:    final.PromptEvalCount,
+		PromptEvalDuration: final.PromptEvalDuration,
+		EvalCount:          final.EvalCount,
+		EvalDuration:       final.EvalDuration,
+	}, nil
 }
@@ -0,0 +1,109 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubLoader is a ConfigLoader that returns fixed values instead of reading
+// folder files, so tests don't need a real folder on disk.
+type stubLoader struct{}
+
+func (stubLoader) LoadConfig(folder string) (ModelConfig, error) {
+	return ModelConfig{Model: "test-model", Options: NewModelOptions()}, nil
+}
+
+func (stubLoader) LoadPrompt(folder string) (string, error) {
+	return "test prompt", nil
+}
+
+func TestGeneratePostRetriesUntilSuccess(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"test-model","response":"ok","done":true}`))
+	}))
+	defer server.Close()
+
+	gen, err := NewGenerate("folder",
+		WithAPIBase(server.URL),
+		WithConfigLoader(stubLoader{}),
+		WithTimeout(5),
+		WithRetry(3, time.Millisecond, 2*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewGenerate() error = %v", err)
+	}
+
+	resp, err := gen.Post(context.Background())
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if resp.Output != "ok" {
+		t.Errorf("Post() Output = %q, want %q", resp.Output, "ok")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d requests, want 3", got)
+	}
+}
+
+func TestGeneratePostGivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	gen, err := NewGenerate("folder",
+		WithAPIBase(server.URL),
+		WithConfigLoader(stubLoader{}),
+		WithTimeout(5),
+		WithRetry(3, time.Millisecond, 2*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewGenerate() error = %v", err)
+	}
+
+	_, err = gen.Post(context.Background())
+	if err == nil {
+		t.Fatal("Post() error = nil, want non-nil after exhausting retries")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d requests, want 3", got)
+	}
+}
+
+func TestGeneratePostNoRetryByDefault(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	gen, err := NewGenerate("folder",
+		WithAPIBase(server.URL),
+		WithConfigLoader(stubLoader{}),
+		WithTimeout(5),
+	)
+	if err != nil {
+		t.Fatalf("NewGenerate() error = %v", err)
+	}
+
+	_, err = gen.Post(context.Background())
+	if err == nil {
+		t.Fatal("Post() error = nil, want non-nil")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("server received %d requests, want 1 (no retry configured)", got)
+	}
+}
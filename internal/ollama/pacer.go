@@ -0,0 +1,65 @@
+package ollama
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Backoff computes how long to sleep before retry attempt n (1-indexed).
+type Backoff interface {
+	Next(attempt int) time.Duration
+}
+
+// pacer is a Backoff that grows the sleep duration exponentially between minSleep
+// and maxSleep, modeled on rclone's pacer: each attempt sleeps minSleep*decay^(n-1),
+// capped at maxSleep, optionally randomized within [0, computed) via full jitter.
+type pacer struct {
+	minSleep time.Duration
+	maxSleep time.Duration
+	decay    float64
+	jitter   bool
+}
+
+// newPacer creates a pacer bounded by minSleep and maxSleep with a decay constant
+// of 2 (the sleep doubles each attempt).
+func newPacer(minSleep, maxSleep time.Duration, jitter bool) *pacer {
+	return &pacer{minSleep: minSleep, maxSleep: maxSleep, decay: 2, jitter: jitter}
+}
+
+// Next returns the sleep duration before retry attempt n.
+func (p *pacer) Next(attempt int) time.Duration {
+	sleep := float64(p.minSleep) * math.Pow(p.decay, float64(attempt-1))
+	if max := float64(p.maxSleep); sleep > max {
+		sleep = max
+	}
+	if p.jitter {
+		sleep = rand.Float64() * sleep
+	}
+	return time.Duration(sleep)
+}
+
+// ShouldRetry reports whether a request that produced resp and err is worth
+// retrying: network errors, and 429, 502, 503, and 504 responses are all
+// transient failures Ollama can recover from on its own.
+func ShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isModelLoadingBody reports whether body is Ollama's "model is loading" error,
+// which it can return with a 500 status while a model is being loaded into memory.
+func isModelLoadingBody(body []byte) bool {
+	return bytes.Contains(bytes.ToLower(body), []byte("model is loading"))
+}
@@ -0,0 +1,62 @@
+package ollama
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPacerNextGrowsAndCaps(t *testing.T) {
+	p := newPacer(10*time.Millisecond, 100*time.Millisecond, false)
+
+	if got := p.Next(1); got != 10*time.Millisecond {
+		t.Errorf("Next(1) = %v, want %v", got, 10*time.Millisecond)
+	}
+	if got := p.Next(2); got != 20*time.Millisecond {
+		t.Errorf("Next(2) = %v, want %v", got, 20*time.Millisecond)
+	}
+	if got := p.Next(3); got != 40*time.Millisecond {
+		t.Errorf("Next(3) = %v, want %v", got, 40*time.Millisecond)
+	}
+	if got := p.Next(10); got != 100*time.Millisecond {
+		t.Errorf("Next(10) = %v, want max %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestPacerNextJitterStaysInRange(t *testing.T) {
+	p := newPacer(10*time.Millisecond, 100*time.Millisecond, true)
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		got := p.Next(attempt)
+		if got < 0 || got > 100*time.Millisecond {
+			t.Fatalf("Next(%d) = %v, want within [0, %v]", attempt, got, 100*time.Millisecond)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	cases := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{"network error", nil, errors.New("boom"), true},
+		{"nil response no error", nil, nil, false},
+		{"429 too many requests", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true},
+		{"502 bad gateway", &http.Response{StatusCode: http.StatusBadGateway}, nil, true},
+		{"503 service unavailable", &http.Response{StatusCode: http.StatusServiceUnavailable}, nil, true},
+		{"504 gateway timeout", &http.Response{StatusCode: http.StatusGatewayTimeout}, nil, true},
+		{"200 ok", &http.Response{StatusCode: http.StatusOK}, nil, false},
+		{"500 internal error", &http.Response{StatusCode: http.StatusInternalServerError}, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ShouldRetry(tc.resp, tc.err); got != tc.want {
+				t.Errorf("ShouldRetry(%v, %v) = %v, want %v", tc.resp, tc.err, got, tc.want)
+			}
+		})
+	}
+}
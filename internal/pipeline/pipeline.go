@@ -0,0 +1,75 @@
+// Package pipeline parses and executes declarative DAG pipeline definitions: an
+// alternative to the linear, comma-separated -f folder chain that lets a node fan
+// out to multiple downstream nodes and take its prompt from more than one upstream
+// output.
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Node is a single step in a pipeline. It runs Folder's config.json+system.txt
+// against a prompt built by rendering Template (a text/template) against the
+// outputs of the nodes listed in Inputs, which may reference another node's ID or
+// the literal "$prompt" for the pipeline's initial prompt. When is optional; if
+// set, it is rendered the same way as Template and the node is skipped unless the
+// rendered result, with surrounding whitespace trimmed, is exactly "true".
+type Node struct {
+	ID       string   `json:"id"`
+	Folder   string   `json:"folder"`
+	Inputs   []string `json:"inputs"`
+	Template string   `json:"template"`
+	When     string   `json:"when"`
+}
+
+// Pipeline is a DAG of Nodes parsed from a pipeline.json file.
+type Pipeline struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// Load reads and parses a pipeline definition from path.
+func Load(path string) (Pipeline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Pipeline{}, fmt.Errorf("error reading pipeline file: %w", err)
+	}
+
+	var p Pipeline
+	if err := json.Unmarshal(content, &p); err != nil {
+		return Pipeline{}, fmt.Errorf("error unmarshalling pipeline file: %w", err)
+	}
+	return p, nil
+}
+
+// Linear synthesizes the Pipeline equivalent of the -f comma-separated folder
+// chain: each folder becomes a node that feeds the next, relaying the previous
+// node's output (with any <think>...</think> block stripped by Run), with the
+// first node taking the pipeline's "$prompt".
+func Linear(folders []string) Pipeline {
+	nodes := make([]Node, len(folders))
+	for i, folder := range folders {
+		if i == 0 {
+			nodes[i] = Node{
+				ID:       folder,
+				Folder:   folder,
+				Inputs:   []string{"$prompt"},
+				Template: "{{.prompt}}",
+			}
+			continue
+		}
+
+		prev := nodes[i-1].ID
+		nodes[i] = Node{
+			ID:     folder,
+			Folder: folder,
+			Inputs: []string{prev},
+			// {{index . "id" "output"}} rather than {{.id.output}}: folder names are
+			// arbitrary strings (commonly kebab-case) and text/template's dotted
+			// field access only allows Go identifier characters after the dot.
+			Template: fmt.Sprintf("{{index . %q \"output\"}}", prev),
+		}
+	}
+	return Pipeline{Nodes: nodes}
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import "testing"
+
+func TestLinearChainsNodesInOrder(t *testing.T) {
+	pl := Linear([]string{"extract", "summarize", "review"})
+
+	if len(pl.Nodes) != 3 {
+		t.Fatalf("len(Nodes) = %d, want 3", len(pl.Nodes))
+	}
+	if pl.Nodes[0].Inputs[0] != "$prompt" {
+		t.Errorf("Nodes[0].Inputs = %v, want first input $prompt", pl.Nodes[0].Inputs)
+	}
+	if pl.Nodes[1].Inputs[0] != "extract" || pl.Nodes[2].Inputs[0] != "summarize" {
+		t.Errorf("nodes do not chain off the previous node's ID: %+v", pl.Nodes)
+	}
+}
+
+func TestLinearTemplatesSurviveHyphenatedFolderNames(t *testing.T) {
+	pl := Linear([]string{"extract-keywords", "summarize-results"})
+
+	ctx := map[string]any{
+		"extract-keywords": map[string]string{"output": "keywords here"},
+	}
+	got, err := render(pl.Nodes[1].Template, ctx)
+	if err != nil {
+		t.Fatalf("rendering template for a hyphenated folder name: %v", err)
+	}
+	if got != "keywords here" {
+		t.Errorf("render() = %q, want %q", got, "keywords here")
+	}
+}
+
+func TestTopoSortOrdersByDependency(t *testing.T) {
+	nodes := []Node{
+		{ID: "c", Inputs: []string{"b"}},
+		{ID: "a", Inputs: []string{"$prompt"}},
+		{ID: "b", Inputs: []string{"a"}},
+	}
+
+	order, err := topoSort(nodes)
+	if err != nil {
+		t.Fatalf("topoSort() error = %v", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, id := range order {
+		pos[id] = i
+	}
+	if pos["a"] > pos["b"] || pos["b"] > pos["c"] {
+		t.Errorf("topoSort() order = %v, want a before b before c", order)
+	}
+}
+
+func TestTopoSortDetectsCycle(t *testing.T) {
+	nodes := []Node{
+		{ID: "a", Inputs: []string{"b"}},
+		{ID: "b", Inputs: []string{"a"}},
+	}
+
+	if _, err := topoSort(nodes); err == nil {
+		t.Fatal("topoSort() error = nil, want a cycle error")
+	}
+}
+
+func TestTopoSortDetectsUnknownDependency(t *testing.T) {
+	nodes := []Node{
+		{ID: "a", Inputs: []string{"missing"}},
+	}
+
+	if _, err := topoSort(nodes); err == nil {
+		t.Fatal("topoSort() error = nil, want an unknown-dependency error")
+	}
+}
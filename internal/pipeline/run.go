@@ -0,0 +1,283 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/ambitiousfew/goll/internal/metrics"
+	"github.com/ambitiousfew/goll/internal/ollama"
+	"github.com/ambitiousfew/goll/internal/tool"
+)
+
+// thinkTagRe matches a reasoning model's <think>...</think> block, as emitted
+// by deepseek-r1-style models. It is stripped from a node's output before
+// that output is fed into a downstream node's template, the same cleanup the
+// old -f chain applied before relaying a response into the next folder's
+// prompt.txt.
+var thinkTagRe = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// configLoaderCacheSize bounds the per-run LRU of parsed folder configs, large
+// enough to cover pipelines over many folders without re-parsing a folder's
+// config.json/system.txt every time a node references it.
+const configLoaderCacheSize = 256
+
+// NodeResult is a single node's completed output, or the error it failed with.
+type NodeResult struct {
+	Output string
+	Err    error
+}
+
+// Result is the outcome of running a full Pipeline.
+type Result struct {
+	Outputs map[string]NodeResult
+	Skipped map[string]bool
+}
+
+// Options controls a pipeline Run.
+type Options struct {
+	Settings      tool.Settings
+	Prompt        string // the pipeline's initial "$prompt" input
+	RunDir        string // directory per-node logs are written under; skipped if empty
+	Parallelism   int    // max nodes running concurrently, from -j. Defaults to 1.
+	MetricsWriter metrics.Writer
+	Verbose       bool
+}
+
+// Run executes p in topological order, running up to opts.Parallelism nodes
+// concurrently, rendering each node's prompt from the outputs of its declared
+// Inputs, and writing a per-node log under opts.RunDir. A node whose When
+// expression does not render to "true" is recorded as skipped and its downstream
+// nodes see it as producing an empty output.
+func Run(ctx context.Context, p Pipeline, opts Options) (Result, error) {
+	order, err := topoSort(p.Nodes)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Nodes may share a folder, and pipelines commonly run over many folders, so
+	// cache parsed folder configs across the whole run instead of per node.
+	loader := ollama.NewCachingLoader(ollama.FSLoader{Base: opts.Settings.FolderBase}, configLoaderCacheSize)
+
+	byID := make(map[string]Node, len(p.Nodes))
+	for _, n := range p.Nodes {
+		byID[n.ID] = n
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	sem := make(chan struct{}, parallelism)
+
+	var mu sync.Mutex
+	result := Result{Outputs: make(map[string]NodeResult), Skipped: make(map[string]bool)}
+
+	done := make(map[string]chan struct{}, len(order))
+	for _, id := range order {
+		done[id] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range order {
+		id := id
+		node := byID[id]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[id])
+
+			for _, input := range node.Inputs {
+				if input == "$prompt" {
+					continue
+				}
+				<-done[input]
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			runNode(ctx, node, opts, loader, &mu, &result)
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// runNode renders node's template and, unless its When expression says to skip
+// it, runs the folder it names and records the outcome in result.
+func runNode(ctx context.Context, node Node, opts Options, loader ollama.ConfigLoader, mu *sync.Mutex, result *Result) {
+	renderCtx := buildRenderContext(opts.Prompt, node.Inputs, mu, result)
+
+	if node.When != "" {
+		run, err := renderBool(node.When, renderCtx)
+		if err != nil {
+			recordResult(mu, result, node.ID, NodeResult{Err: fmt.Errorf("error rendering when expression: %w", err)})
+			return
+		}
+		if !run {
+			mu.Lock()
+			result.Skipped[node.ID] = true
+			mu.Unlock()
+			return
+		}
+	}
+
+	prompt, err := render(node.Template, renderCtx)
+	if err != nil {
+		recordResult(mu, result, node.ID, NodeResult{Err: fmt.Errorf("error rendering template: %w", err)})
+		return
+	}
+
+	gen, err := tool.NewGenerator(node.Folder, opts.Settings.FolderBase, opts.Settings, prompt, loader)
+	if err != nil {
+		recordResult(mu, result, node.ID, NodeResult{Err: fmt.Errorf("error creating generate instance: %w", err)})
+		return
+	}
+
+	resp, err := tool.Run(ctx, &gen, false, nil)
+	recordResult(mu, result, node.ID, NodeResult{Output: resp.Output, Err: err})
+
+	if opts.Verbose && err == nil {
+		fmt.Printf("%s completed successfully\n", node.ID)
+	}
+
+	if opts.MetricsWriter != nil && err == nil {
+		point := metrics.NewPoint(resp, node.Folder, gen.ModelConfig.Model)
+		if merr := opts.MetricsWriter.Write(ctx, point); merr != nil {
+			fmt.Printf("Error writing metrics for node %s: %v\n", node.ID, merr)
+		}
+	}
+
+	if logErr := writeNodeLog(opts.RunDir, node.ID, prompt, resp, err); logErr != nil {
+		fmt.Printf("Error writing pipeline log for node %s: %v\n", node.ID, logErr)
+	}
+}
+
+func recordResult(mu *sync.Mutex, result *Result, id string, nr NodeResult) {
+	mu.Lock()
+	result.Outputs[id] = nr
+	mu.Unlock()
+}
+
+// buildRenderContext builds the data passed to text/template for a node's
+// Template and When expressions: "prompt" when $prompt is an input, plus one
+// entry per other declared input keyed by its node ID with an "output" field.
+func buildRenderContext(prompt string, inputs []string, mu *sync.Mutex, result *Result) map[string]any {
+	ctx := make(map[string]any, len(inputs))
+	mu.Lock()
+	defer mu.Unlock()
+	for _, input := range inputs {
+		if input == "$prompt" {
+			ctx["prompt"] = prompt
+			continue
+		}
+		ctx[input] = map[string]string{"output": thinkTagRe.ReplaceAllString(result.Outputs[input].Output, "")}
+	}
+	return ctx
+}
+
+// render executes a text/template string against data.
+func render(tmplText string, data any) (string, error) {
+	tmpl, err := template.New("node").Parse(tmplText)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// renderBool renders a When expression and reports whether it is exactly "true"
+// once surrounding whitespace is trimmed.
+// Expressions are text/templates rather than a full CEL/govaluate evaluator so
+// they can reference upstream outputs the same way Template does, e.g.
+// `{{if eq .extract.output ""}}false{{else}}true{{end}}`.
+func renderBool(whenText string, data any) (bool, error) {
+	out, err := render(whenText, data)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
+
+// topoSort returns nodes in an order where every node appears after all of its
+// non-"$prompt" Inputs. It returns an error if a node depends on an unknown node
+// or the graph contains a cycle.
+func topoSort(nodes []Node) ([]string, error) {
+	ids := make(map[string]bool, len(nodes))
+	indegree := make(map[string]int, len(nodes))
+	deps := make(map[string][]string, len(nodes)) // node -> nodes that depend on it
+
+	for _, n := range nodes {
+		ids[n.ID] = true
+		indegree[n.ID] = 0
+	}
+	for _, n := range nodes {
+		for _, input := range n.Inputs {
+			if input == "$prompt" {
+				continue
+			}
+			if !ids[input] {
+				return nil, fmt.Errorf("node %q depends on unknown node %q", n.ID, input)
+			}
+			indegree[n.ID]++
+			deps[input] = append(deps[input], n.ID)
+		}
+	}
+
+	queue := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		if indegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+
+	order := make([]string, 0, len(nodes))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, next := range deps[id] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if len(order) != len(nodes) {
+		return nil, fmt.Errorf("pipeline contains a cycle")
+	}
+	return order, nil
+}
+
+// writeNodeLog writes a single node's prompt/response (or error) to
+// runDir/<id>.log. It is a no-op if runDir is empty.
+func writeNodeLog(runDir, id, prompt string, resp ollama.Response, runErr error) error {
+	if runDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fmt.Errorf("error creating run directory: %w", err)
+	}
+
+	var body string
+	if runErr != nil {
+		body = fmt.Sprintf("Prompt: %s\n\nError: %v\n", prompt, runErr)
+	} else {
+		body = fmt.Sprintf("Prompt: %s\n\nResponse: %s\n", prompt, resp.Output)
+	}
+
+	logPath := filepath.Join(runDir, id+".log")
+	return os.WriteFile(logPath, []byte(body), 0644)
+}
@@ -0,0 +1,204 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ambitiousfew/goll/internal/tool"
+)
+
+// writeFolder creates base/folder/config.json and system.txt so FSLoader (used
+// internally by Run) can load it without talking to a real Ollama install.
+func writeFolder(t *testing.T, base, folder string) {
+	t.Helper()
+	dir := filepath.Join(base, folder)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("creating folder %s: %v", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"model":"test-model"}`), 0644); err != nil {
+		t.Fatalf("writing config.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "system.txt"), []byte("system prompt"), 0644); err != nil {
+		t.Fatalf("writing system.txt: %v", err)
+	}
+}
+
+// fakeOllama replies with the prompt it received wrapped in an identifiable
+// response, so a test can assert on exactly what each node was rendered with.
+func fakeOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		resp := map[string]any{
+			"model":    "test-model",
+			"response": "got: " + req.Prompt,
+			"done":     true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestRunLinearChainWithHyphenatedFolderNames(t *testing.T) {
+	base := t.TempDir()
+	writeFolder(t, base, "extract-keywords")
+	writeFolder(t, base, "summarize-results")
+
+	server := fakeOllama(t)
+	defer server.Close()
+
+	pl := Linear([]string{"extract-keywords", "summarize-results"})
+	result, err := Run(context.Background(), pl, Options{
+		Settings: tool.Settings{APIBase: server.URL, FolderBase: base, Timeout: 5},
+		Prompt:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	first := result.Outputs["extract-keywords"]
+	if first.Err != nil {
+		t.Fatalf("extract-keywords failed: %v", first.Err)
+	}
+	if first.Output != "got: hello" {
+		t.Errorf("extract-keywords output = %q, want %q", first.Output, "got: hello")
+	}
+
+	second := result.Outputs["summarize-results"]
+	if second.Err != nil {
+		t.Fatalf("summarize-results failed: %v", second.Err)
+	}
+	if second.Output != "got: got: hello" {
+		t.Errorf("summarize-results output = %q, want %q", second.Output, "got: got: hello")
+	}
+}
+
+func TestRunStripsThinkTagsBeforeRelayingToDownstreamNode(t *testing.T) {
+	base := t.TempDir()
+	writeFolder(t, base, "reason")
+	writeFolder(t, base, "answer")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		var out string
+		if req.Prompt == "hello" {
+			out = "<think>reasoning that should not leak</think>final answer"
+		} else {
+			out = "relayed: " + req.Prompt
+		}
+		json.NewEncoder(w).Encode(map[string]any{"model": "test-model", "response": out, "done": true})
+	}))
+	defer server.Close()
+
+	pl := Linear([]string{"reason", "answer"})
+	result, err := Run(context.Background(), pl, Options{
+		Settings: tool.Settings{APIBase: server.URL, FolderBase: base, Timeout: 5},
+		Prompt:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := result.Outputs["reason"].Output; got != "<think>reasoning that should not leak</think>final answer" {
+		t.Errorf("reason's own stored output = %q, want the raw response untouched", got)
+	}
+	if got := result.Outputs["answer"].Output; got != "relayed: final answer" {
+		t.Errorf("answer output = %q, want the think-block stripped before relay", got)
+	}
+}
+
+func TestRunSkipsNodeWhenExpressionIsFalse(t *testing.T) {
+	base := t.TempDir()
+	writeFolder(t, base, "a")
+	writeFolder(t, base, "b")
+
+	server := fakeOllama(t)
+	defer server.Close()
+
+	pl := Pipeline{Nodes: []Node{
+		{ID: "a", Folder: "a", Inputs: []string{"$prompt"}, Template: "{{.prompt}}"},
+		{ID: "b", Folder: "b", Inputs: []string{"a"}, Template: `{{index . "a" "output"}}`, When: "false"},
+	}}
+
+	result, err := Run(context.Background(), pl, Options{
+		Settings: tool.Settings{APIBase: server.URL, FolderBase: base, Timeout: 5},
+		Prompt:   "hello",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !result.Skipped["b"] {
+		t.Error(`result.Skipped["b"] = false, want true`)
+	}
+	if _, ran := result.Outputs["b"]; ran {
+		t.Error(`result.Outputs["b"] present, want node b to never have run`)
+	}
+}
+
+// TestRunSerializesIndependentNodesWhenParallelismIsOne runs three nodes with
+// no dependency on each other through a server that reports whether any other
+// request was in flight at the time it was handled. With Parallelism left at
+// its default of 1, Run's semaphore should never let two requests overlap
+// even though the nodes are not ordered by topoSort's dependency edges.
+func TestRunSerializesIndependentNodesWhenParallelismIsOne(t *testing.T) {
+	base := t.TempDir()
+	for _, f := range []string{"a", "b", "c"} {
+		writeFolder(t, base, f)
+	}
+
+	var mu sync.Mutex
+	active := 0
+	overlapped := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > 1 {
+			overlapped = true
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"model": "test-model", "response": "ok", "done": true})
+	}))
+	defer server.Close()
+
+	pl := Pipeline{Nodes: []Node{
+		{ID: "a", Folder: "a", Inputs: []string{"$prompt"}, Template: "{{.prompt}}"},
+		{ID: "b", Folder: "b", Inputs: []string{"$prompt"}, Template: "{{.prompt}}"},
+		{ID: "c", Folder: "c", Inputs: []string{"$prompt"}, Template: "{{.prompt}}"},
+	}}
+
+	if _, err := Run(context.Background(), pl, Options{
+		Settings: tool.Settings{APIBase: server.URL, FolderBase: base, Timeout: 5},
+		Prompt:   "hello",
+	}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if overlapped {
+		t.Error("two requests overlapped, want the default Parallelism=1 to serialize independent nodes")
+	}
+}
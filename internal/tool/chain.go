@@ -0,0 +1,121 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ambitiousfew/goll/internal/metrics"
+	"github.com/ambitiousfew/goll/internal/ollama"
+)
+
+// FolderRun is the result of RunFolder: the generated response together with
+// the pretty-printed model config used to produce it.
+type FolderRun struct {
+	Response        ollama.Response
+	ModelConfigJSON []byte
+}
+
+// RunFolder builds and executes a single folder's Generate call end to end:
+// construction, buffered-or-streamed execution (streamed with a live-printed
+// response when verbose, buffered behind spin otherwise), metrics emission,
+// and writing an output_<timestamp>.log inside the folder. It is the
+// chain-iteration step shared by every front end that walks a list of
+// folders one at a time independently (the CLI's -r recursion; -f itself is
+// routed through the DAG pipeline engine instead, which has its own
+// per-node execution in internal/pipeline).
+//
+// spin may be nil when verbose is true, since the spinner is never started
+// for a streamed call. If ctx is cancelled while waiting for the spinner,
+// RunFolder returns ctx.Err() so the caller can stop the chain without
+// treating it as a failure.
+func RunFolder(ctx context.Context, settings Settings, folderBase, folder, prompt string, loader ollama.ConfigLoader, metricsWriter metrics.Writer, verbose bool, spin chan<- bool) (FolderRun, error) {
+	empty := FolderRun{}
+
+	gen, err := NewGenerator(folder, folderBase, settings, prompt, loader)
+	if err != nil {
+		return empty, fmt.Errorf("error creating generate instance: %w", err)
+	}
+
+	modelConfigJSON, err := json.MarshalIndent(gen.ModelConfig, "", "  ")
+	if err != nil {
+		return empty, fmt.Errorf("error marshalling modelConfig: %w", err)
+	}
+
+	if verbose {
+		fmt.Printf("Generating response using folder: %s\n  With Model Config: %v\n", folder, string(modelConfigJSON))
+	}
+
+	var resp ollama.Response
+	if verbose {
+		// Stream the response so tokens print live instead of behind a spinner.
+		fmt.Printf("\n\nResponse: ")
+		resp, err = Run(ctx, &gen, true, func(chunk ollama.StreamChunk) error {
+			fmt.Print(chunk.Response)
+			return nil
+		})
+		if err != nil {
+			return empty, fmt.Errorf("error generating response: %w", err)
+		}
+	} else {
+		select {
+		case <-ctx.Done():
+			return empty, ctx.Err()
+		case spin <- true:
+		}
+
+		resp, err = Run(ctx, &gen, false, nil)
+		if err != nil {
+			return empty, fmt.Errorf("error generating response: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return empty, ctx.Err()
+		case spin <- false:
+		}
+	}
+
+	evalTime := float64(resp.EvalDuration) / 1e9
+	var tps float64
+	if evalTime != 0 {
+		tps = float64(resp.EvalCount) / evalTime
+	}
+
+	if verbose {
+		fmt.Printf("\n\nGenerated %d tokens in %.2f seconds", resp.EvalCount, evalTime)
+		fmt.Printf("\nTokens per second: %.2f\n", tps)
+	}
+
+	// Emit the call's metrics to the configured sink. A metrics failure should
+	// not take down the chain, so it is logged rather than returned.
+	point := metrics.NewPoint(resp, folder, gen.ModelConfig.Model)
+	if err := metricsWriter.Write(ctx, point); err != nil {
+		fmt.Printf("Error writing metrics: %v\n", err)
+	}
+
+	outputLogFileName := fmt.Sprintf("output_%s.log", time.Now().Format("2006-01-02_15-04-05"))
+	outputLogPath := filepath.Join(folderBase, folder, outputLogFileName)
+	outputLog := fmt.Sprintf(
+		"Prompt: %s\n\n"+
+			"Response: %s\n\n"+
+			"Generated %d tokens in %.2f seconds\n"+
+			"Tokens per second: %.2f\n"+
+			"Using model config: %s\n",
+		gen.Prompt,
+		resp.Output,
+		resp.EvalCount,
+		evalTime,
+		tps,
+		modelConfigJSON,
+	)
+	if err := os.WriteFile(outputLogPath, []byte(outputLog), 0644); err != nil {
+		return empty, fmt.Errorf("error writing output.log: %w", err)
+	}
+	fmt.Printf("Output written to %s\n", outputLogPath)
+
+	return FolderRun{Response: resp, ModelConfigJSON: modelConfigJSON}, nil
+}
@@ -0,0 +1,72 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ambitiousfew/goll/internal/ollama"
+)
+
+// defaultRetryMinSleep and defaultRetryMaxSleep bound the pacer used when
+// Settings.Retry enables retries but leaves the sleep window unset.
+const (
+	defaultRetryMinSleep = 500 * time.Millisecond
+	defaultRetryMaxSleep = 5 * time.Second
+)
+
+// NewGenerator builds an ollama.Generate for folder using settings, optionally
+// overriding the folder base path (used when recursing through subfolders) and
+// the initial prompt text. It is the construction step shared by the CLI and the
+// HTTP server so both front ends wire up ollama.Generate the same way.
+//
+// loader may be nil, in which case ollama.Generate falls back to its own
+// FSLoader default. Callers that create many Generate instances in one run
+// (a recursive -r chain, a pipeline, the HTTP server's request loop) should
+// build a single ollama.NewCachingLoader and pass it to every call so a
+// folder's config.json/system.txt is only parsed once.
+//
+// If settings.Retry.MaxAttempts is greater than 1, Post retries transient
+// failures (see ollama.ShouldRetry) with a pacer bounded by
+// settings.Retry.MinSleepMS/MaxSleepMS, falling back to
+// defaultRetryMinSleep/defaultRetryMaxSleep for either bound left at zero.
+func NewGenerator(folder, folderBase string, settings Settings, prompt string, loader ollama.ConfigLoader) (ollama.Generate, error) {
+	opts := []ollama.Option{
+		ollama.WithPrompt(prompt),
+		ollama.WithAPIBase(settings.APIBase),
+		ollama.WithFolderBase(folderBase),
+		ollama.WithClient(http.Client{}),
+		ollama.WithTimeout(settings.Timeout),
+	}
+	if loader != nil {
+		opts = append(opts, ollama.WithConfigLoader(loader))
+	}
+	if settings.Retry.MaxAttempts > 1 {
+		minSleep := time.Duration(settings.Retry.MinSleepMS) * time.Millisecond
+		if minSleep <= 0 {
+			minSleep = defaultRetryMinSleep
+		}
+		maxSleep := time.Duration(settings.Retry.MaxSleepMS) * time.Millisecond
+		if maxSleep <= 0 {
+			maxSleep = defaultRetryMaxSleep
+		}
+		opts = append(opts, ollama.WithRetry(settings.Retry.MaxAttempts, minSleep, maxSleep))
+	}
+	return ollama.NewGenerate(folder, opts...)
+}
+
+// Run executes gen, either buffered through ollama.Generate.Post or, when stream
+// is true, streamed through ollama.Generate.Stream with onChunk invoked for each
+// partial chunk. It is the chain-execution step shared by the CLI and the HTTP
+// server so the stream-vs-buffered decision lives in one place.
+func Run(ctx context.Context, gen *ollama.Generate, stream bool, onChunk func(ollama.StreamChunk) error) (ollama.Response, error) {
+	if stream {
+		return gen.Stream(ctx, func(chunk ollama.StreamChunk) error {
+			if onChunk != nil {
+				return onChunk(chunk)
+			}
+			return nil
+		})
+	}
+	return gen.Post(ctx)
+}
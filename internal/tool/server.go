@@ -0,0 +1,265 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ambitiousfew/goll/internal/ollama"
+)
+
+// serverConfigLoaderCacheSize bounds the server's LRU of parsed folder configs,
+// shared across the server's full lifetime since the same folders tend to be
+// requested repeatedly over many requests.
+const serverConfigLoaderCacheSize = 256
+
+// Server exposes the chain runner over HTTP: plain chain endpoints plus an
+// OpenAI-compatible chat completions endpoint, so editors, agents, and CI can
+// drive goll as a long-running service instead of a one-shot CLI.
+type Server struct {
+	Settings Settings
+	loader   ollama.ConfigLoader
+}
+
+// NewServer creates a Server backed by the given settings.
+func NewServer(settings Settings) *Server {
+	return &Server{
+		Settings: settings,
+		loader:   ollama.NewCachingLoader(ollama.FSLoader{Base: settings.FolderBase}, serverConfigLoaderCacheSize),
+	}
+}
+
+// Handler builds the http.Handler exposing the server's routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/chains", s.handleListChains)
+	mux.HandleFunc("POST /v1/chains/{folder}/run", s.handleRunChain)
+	mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	return mux
+}
+
+// handleListChains lists the folder names available under Settings.FolderBase.
+func (s *Server) handleListChains(w http.ResponseWriter, r *http.Request) {
+	entries, err := os.ReadDir(s.Settings.FolderBase)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error reading folder_base_path: %w", err))
+		return
+	}
+
+	chains := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			chains = append(chains, entry.Name())
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"chains": chains})
+}
+
+// chainRunRequest is the body accepted by POST /v1/chains/{folder}/run.
+type chainRunRequest struct {
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+// handleRunChain runs a single folder and returns its Response, either as a single
+// JSON object or, when Stream is true, as a series of Server-Sent Events.
+func (s *Server) handleRunChain(w http.ResponseWriter, r *http.Request) {
+	folder := r.PathValue("folder")
+
+	var req chainRunRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("error decoding request body: %w", err))
+			return
+		}
+	}
+
+	gen, err := NewGenerator(folder, s.Settings.FolderBase, s.Settings, req.Prompt, s.loader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("error creating generate instance: %w", err))
+		return
+	}
+
+	if !req.Stream {
+		resp, err := Run(r.Context(), &gen, false, nil)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	sse, flush, ok := newSSEWriter(w)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported by response writer"))
+		return
+	}
+
+	_, err = Run(r.Context(), &gen, true, func(chunk ollama.StreamChunk) error {
+		if err := sse.writeJSON(chunk); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		// The stream is already committed at this point, so the error is sent as
+		// a final event rather than an HTTP status code.
+		sse.writeJSON(map[string]string{"error": err.Error()})
+	}
+	sse.writeDone()
+	flush()
+}
+
+// chatMessage mirrors the OpenAI chat message shape.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletionRequest mirrors the subset of the OpenAI chat completions request
+// goll understands: Model selects the folder whose config.json+system.txt is used,
+// and the last message's content becomes the prompt.
+type chatCompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// handleChatCompletions maps an OpenAI-compatible chat request onto a folder run,
+// so goll can be pointed at by any client that already speaks the OpenAI API.
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req chatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("error decoding request body: %w", err))
+		return
+	}
+	if req.Model == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("model is required"))
+		return
+	}
+
+	var prompt string
+	if len(req.Messages) > 0 {
+		prompt = req.Messages[len(req.Messages)-1].Content
+	}
+
+	gen, err := NewGenerator(req.Model, s.Settings.FolderBase, s.Settings, prompt, s.loader)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("error creating generate instance: %w", err))
+		return
+	}
+
+	id := fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	created := time.Now().Unix()
+
+	if !req.Stream {
+		resp, err := Run(r.Context(), &gen, false, nil)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"id":      id,
+			"object":  "chat.completion",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]any{
+				{
+					"index":         0,
+					"message":       chatMessage{Role: "assistant", Content: resp.Output},
+					"finish_reason": "stop",
+				},
+			},
+		})
+		return
+	}
+
+	sse, flush, ok := newSSEWriter(w)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported by response writer"))
+		return
+	}
+
+	_, err = Run(r.Context(), &gen, true, func(chunk ollama.StreamChunk) error {
+		finishReason := any(nil)
+		if chunk.Done {
+			finishReason = "stop"
+		}
+		if err := sse.writeJSON(map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]any{
+				{
+					"index":         0,
+					"delta":         chatMessage{Content: chunk.Response},
+					"finish_reason": finishReason,
+				},
+			},
+		}); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		sse.writeJSON(map[string]string{"error": err.Error()})
+	}
+	sse.writeDone()
+	flush()
+}
+
+// writeJSON writes v as a JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error body with the given status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// sseWriter writes Server-Sent Events to an underlying http.ResponseWriter.
+type sseWriter struct {
+	w http.ResponseWriter
+}
+
+// newSSEWriter sets the headers for a Server-Sent Events response and returns a
+// writer for it along with a flush function. ok is false if the ResponseWriter
+// does not support flushing, in which case no headers are written.
+func newSSEWriter(w http.ResponseWriter) (sseWriter, func(), bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return sseWriter{}, nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	return sseWriter{w: w}, flusher.Flush, true
+}
+
+// writeJSON writes v as a single "data: ..." SSE event.
+func (s sseWriter) writeJSON(v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "data: %s\n\n", payload)
+	return err
+}
+
+// writeDone writes the terminal "[DONE]" event OpenAI-compatible clients expect.
+func (s sseWriter) writeDone() {
+	fmt.Fprint(s.w, "data: [DONE]\n\n")
+}
@@ -1,10 +1,24 @@
 // Package tool contains the Settings struct that is used to store the global settings for goll.
 package tool
 
+import "github.com/ambitiousfew/goll/internal/metrics"
+
 // Settings struct contains the global settings for goll.
 // Set from the settings.json file.
 type Settings struct {
-	APIBase    string `json:"api_base_url"`
-	FolderBase string `json:"folder_base_path"`
-	Timeout    int    `json:"timeout"`
+	APIBase    string         `json:"api_base_url"`
+	FolderBase string         `json:"folder_base_path"`
+	Timeout    int            `json:"timeout"`
+	Metrics    metrics.Config `json:"metrics"`
+	Retry      RetryConfig    `json:"retry"`
+}
+
+// RetryConfig configures the retry/backoff pacer wrapped around every
+// ollama.Generate.Post call. A zero value (the default when "retry" is absent
+// from settings.json) disables retries, matching ollama.Generate's own
+// default of a single attempt.
+type RetryConfig struct {
+	MaxAttempts int `json:"max_attempts"`
+	MinSleepMS  int `json:"min_sleep_ms"` // lower bound for the pacer. Defaults to 500ms if MaxAttempts > 1 and unset.
+	MaxSleepMS  int `json:"max_sleep_ms"` // upper bound for the pacer. Defaults to 5000ms if MaxAttempts > 1 and unset.
 }